@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// genSpec describes one +grab:generate annotated type.
+type genSpec struct {
+	typeName    string
+	constructor string // empty if the grabber should just return a zero value
+}
+
+// parsedFile holds the AST of a scanned source file alongside the
+// +grab:generate specs found in it.
+type parsedFile struct {
+	file  *ast.File
+	specs []*genSpec
+}
+
+// parseFile scans path for type declarations annotated with
+// "+grab:generate", a doc comment directly above the type:
+//
+//	// +grab:generate
+//	type Foo struct{ ... }
+//
+// An optional constructor name wires the grabber to an existing function in
+// the same file instead of a bare zero value:
+//
+//	// +grab:generate NewFoo
+//	type Foo struct{ ... }
+//
+// NewFoo's parameters are resolved by naming convention: a parameter of type
+// *pkg.Dep (or Dep) is satisfied by a package-level Grab<Dep> variable.
+func parseFile(path string) (*parsedFile, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	pf := &parsedFile{file: f}
+
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+
+		for _, s := range gd.Specs {
+			ts, ok := s.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			doc := ts.Doc
+			if doc == nil {
+				doc = gd.Doc
+			}
+			if doc == nil {
+				continue
+			}
+
+			ctor, ok := directive(doc)
+			if !ok {
+				continue
+			}
+
+			pf.specs = append(pf.specs, &genSpec{typeName: ts.Name.Name, constructor: ctor})
+		}
+	}
+
+	return pf, nil
+}
+
+// directive reports the constructor name (possibly empty) carried by a
+// "+grab:generate [Ctor]" comment line, if doc has one.
+func directive(doc *ast.CommentGroup) (string, bool) {
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if !strings.HasPrefix(text, "+grab:generate") {
+			continue
+		}
+		return strings.TrimSpace(strings.TrimPrefix(text, "+grab:generate")), true
+	}
+	return "", false
+}
+
+// findFunc looks up a package-level function declaration by name.
+func findFunc(f *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if ok && fd.Recv == nil && fd.Name.Name == name {
+			return fd
+		}
+	}
+	return nil
+}
+
+// generate renders the "<file>_grab.go" source for every spec found in pf.
+func generate(pf *parsedFile, withMock bool) ([]byte, error) {
+	im := newImportManager()
+
+	blocks := make([]string, 0, len(pf.specs))
+	for _, spec := range pf.specs {
+		block, err := generateSpec(pf, spec, im, withMock)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.typeName, err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by grabgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pf.file.Name.Name)
+
+	b.WriteString("import (\n\t\"github.com/nulloop/grab/v2\"\n")
+	for _, path := range im.orderedPaths() {
+		fmt.Fprintf(&b, "\t%s %q\n", im.aliasByPath[path], path)
+	}
+	b.WriteString(")\n\n")
+
+	for _, block := range blocks {
+		b.WriteString(block)
+		b.WriteString("\n")
+	}
+
+	return format.Source([]byte(b.String()))
+}
+
+// generateSpec renders the Grab<Name>/Get<Name>(/Mock<Name>) block for a
+// single spec.
+func generateSpec(pf *parsedFile, spec *genSpec, im *importManager, withMock bool) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Grab%s is the generated Grabber for %s.\n", spec.typeName, spec.typeName)
+	fmt.Fprintf(&b, "var Grab%s = grab.Func(func(c grab.Container) (interface{}, error) {\n", spec.typeName)
+
+	if spec.constructor == "" {
+		fmt.Fprintf(&b, "\treturn &%s{}, nil\n", spec.typeName)
+	} else {
+		fn := findFunc(pf.file, spec.constructor)
+		if fn == nil {
+			return "", fmt.Errorf("constructor %s not found in file", spec.constructor)
+		}
+
+		args, err := writeDeps(&b, pf.file, fn, im)
+		if err != nil {
+			return "", err
+		}
+
+		results := 1
+		if fn.Type.Results != nil {
+			results = len(fn.Type.Results.List)
+		}
+
+		if results >= 2 {
+			fmt.Fprintf(&b, "\treturn %s(%s)\n", spec.constructor, strings.Join(args, ", "))
+		} else {
+			fmt.Fprintf(&b, "\tv := %s(%s)\n\treturn v, nil\n", spec.constructor, strings.Join(args, ", "))
+		}
+	}
+
+	b.WriteString("})\n\n")
+
+	fmt.Fprintf(&b, "// Get%s resolves Grab%s against c.\n", spec.typeName, spec.typeName)
+	fmt.Fprintf(&b, "func Get%s(c grab.Container) (*%s, error) {\n", spec.typeName, spec.typeName)
+	fmt.Fprintf(&b, "\tvar v *%s\n", spec.typeName)
+	fmt.Fprintf(&b, "\tif err := c.Get(&v, Grab%s); err != nil {\n\t\treturn nil, err\n\t}\n", spec.typeName)
+	b.WriteString("\treturn v, nil\n}\n")
+
+	if withMock {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "// Mock%s mocks Grab%s on r.\n", spec.typeName, spec.typeName)
+		fmt.Fprintf(&b, "func Mock%s(r *grab.RepositoryWithMock, v *%s) error {\n", spec.typeName, spec.typeName)
+		fmt.Fprintf(&b, "\treturn r.Mock(Grab%s, v)\n}\n", spec.typeName)
+	}
+
+	return b.String(), nil
+}
+
+// writeDeps emits a "var dep<i> <Type>; c.Get(&dep<i>, Grab<Type>)" block for
+// every parameter of fn, returning the dep variable names in order so the
+// caller can splice them into the constructor call.
+func writeDeps(b *strings.Builder, f *ast.File, fn *ast.FuncDecl, im *importManager) ([]string, error) {
+	var args []string
+
+	for i, field := range fn.Type.Params.List {
+		typeStr, err := renderType(field.Type, f, im)
+		if err != nil {
+			return nil, err
+		}
+
+		grabName, err := depGrabberName(field.Type, f, im)
+		if err != nil {
+			return nil, err
+		}
+
+		depVar := fmt.Sprintf("dep%d", i)
+		fmt.Fprintf(b, "\tvar %s %s\n", depVar, typeStr)
+		fmt.Fprintf(b, "\tif err := c.Get(&%s, %s); err != nil {\n\t\treturn nil, err\n\t}\n\n", depVar, grabName)
+		args = append(args, depVar)
+	}
+
+	return args, nil
+}
+
+// renderType renders a parameter type as source text, rewriting any
+// qualified package identifier through im so the alias used matches the
+// import block of the generated file.
+func renderType(expr ast.Expr, f *ast.File, im *importManager) (string, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		inner, err := renderType(t.X, f, im)
+		if err != nil {
+			return "", err
+		}
+		return "*" + inner, nil
+	case *ast.Ident:
+		return t.Name, nil
+	case *ast.SelectorExpr:
+		alias, err := resolveImportAlias(f, im, t.X)
+		if err != nil {
+			return "", err
+		}
+		return alias + "." + t.Sel.Name, nil
+	default:
+		return "", fmt.Errorf("unsupported parameter type %T", expr)
+	}
+}
+
+// resolveImportAlias resolves the package identifier of a qualified type
+// (the X in "pkg.Foo") back to its ImportSpec and registers it with im,
+// returning the alias the generated file uses for that import.
+func resolveImportAlias(f *ast.File, im *importManager, pkgExpr ast.Expr) (string, error) {
+	pkgIdent, ok := pkgExpr.(*ast.Ident)
+	if !ok {
+		return "", fmt.Errorf("unsupported selector expression %v", pkgExpr)
+	}
+
+	spec := importSpecFor(f, pkgIdent.Name)
+	if spec == nil {
+		return "", fmt.Errorf("could not resolve import for package %q", pkgIdent.Name)
+	}
+
+	path := strings.Trim(spec.Path.Value, `"`)
+	sourceAlias := ""
+	if spec.Name != nil {
+		sourceAlias = spec.Name.Name
+	}
+
+	return im.add(path, sourceAlias, defaultPackageName(path)), nil
+}
+
+// depGrabberName derives the conventional Grab<Dep> reference for a
+// constructor parameter type. For a type from another package, this assumes
+// that package was itself generated with grabgen, so Grab<Dep> is a
+// package-level var next to the type; the reference is qualified with the
+// same alias renderType uses for that package's type.
+func depGrabberName(expr ast.Expr, f *ast.File, im *importManager) (string, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return depGrabberName(t.X, f, im)
+	case *ast.Ident:
+		return "Grab" + t.Name, nil
+	case *ast.SelectorExpr:
+		alias, err := resolveImportAlias(f, im, t.X)
+		if err != nil {
+			return "", err
+		}
+		return alias + ".Grab" + t.Sel.Name, nil
+	default:
+		return "", fmt.Errorf("unsupported parameter type %T", expr)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateZeroValueAndWiredConstructor(t *testing.T) {
+	pf, err := parseFile("testdata/sample.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pf.specs) != 2 {
+		t.Fatalf("expected 2 annotated types, got %d", len(pf.specs))
+	}
+
+	out, err := generate(pf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := string(out)
+
+	for _, want := range []string{
+		"var GrabPlain = grab.Func(",
+		"return &Plain{}, nil",
+		"func GetPlain(c grab.Container) (*Plain, error) {",
+		"var GrabWired = grab.Func(",
+		"var dep0 *Plain",
+		"c.Get(&dep0, GrabPlain)",
+		"return NewWired(dep0)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateWithMock(t *testing.T) {
+	pf, err := parseFile("testdata/sample.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := generate(pf, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := string(out)
+	if !strings.Contains(src, "func MockPlain(r *grab.RepositoryWithMock, v *Plain) error {") {
+		t.Errorf("generated source missing MockPlain helper:\n%s", src)
+	}
+}
+
+func TestGenerateQualifiesCrossPackageDependency(t *testing.T) {
+	pf, err := parseFile("testdata/cross_package.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := generate(pf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := string(out)
+
+	for _, want := range []string{
+		`db "example.com/other/db"`,
+		"var dep0 *db.Conn",
+		"c.Get(&dep0, db.GrabConn)",
+		"return NewRemote(dep0)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestImportManagerDisambiguatesCollidingAliases(t *testing.T) {
+	im := newImportManager()
+
+	a := im.add("example.com/one/db", "", "db")
+	b := im.add("example.com/two/db", "", "db")
+
+	if a == b {
+		t.Fatalf("expected distinct aliases for colliding package names, got %q for both", a)
+	}
+
+	// re-adding the same path must keep returning the same alias
+	if again := im.add("example.com/one/db", "", "db"); again != a {
+		t.Fatalf("expected stable alias %q, got %q", a, again)
+	}
+}
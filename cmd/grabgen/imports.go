@@ -0,0 +1,86 @@
+package main
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// importManager assigns each referenced import path a stable, collision-free
+// alias for the generated file. It reuses the alias the source file already
+// gave the import, falling back to the package's default name otherwise -
+// the same approach mockgen/moq use so regenerated files diff cleanly.
+type importManager struct {
+	aliasByPath map[string]string
+	pathByAlias map[string]string
+}
+
+func newImportManager() *importManager {
+	return &importManager{
+		aliasByPath: make(map[string]string),
+		pathByAlias: make(map[string]string),
+	}
+}
+
+// add registers path (seen via the given source alias, or def if the source
+// import was unaliased) and returns the alias to use for it in the
+// generated file.
+func (im *importManager) add(path, sourceAlias, def string) string {
+	if alias, ok := im.aliasByPath[path]; ok {
+		return alias
+	}
+
+	alias := sourceAlias
+	if alias == "" {
+		alias = def
+	}
+
+	for {
+		existing, taken := im.pathByAlias[alias]
+		if !taken || existing == path {
+			break
+		}
+		alias += "_"
+	}
+
+	im.aliasByPath[path] = alias
+	im.pathByAlias[alias] = path
+	return alias
+}
+
+// orderedPaths returns the registered import paths in a deterministic order.
+func (im *importManager) orderedPaths() []string {
+	paths := make([]string, 0, len(im.aliasByPath))
+	for p := range im.aliasByPath {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// importSpecFor finds the ImportSpec in f backing the package identifier
+// used in a qualified type such as "pkg.Foo".
+func importSpecFor(f *ast.File, pkgIdent string) *ast.ImportSpec {
+	for _, imp := range f.Imports {
+		name := ""
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+
+		path := strings.Trim(imp.Path.Value, `"`)
+
+		if name == pkgIdent || (name == "" && defaultPackageName(path) == pkgIdent) {
+			return imp
+		}
+	}
+	return nil
+}
+
+// defaultPackageName approximates the package name Go would infer for an
+// unaliased import, from its last path segment.
+func defaultPackageName(path string) string {
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		return path[idx+1:]
+	}
+	return path
+}
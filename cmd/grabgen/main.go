@@ -0,0 +1,62 @@
+// Command grabgen generates Grabber constructors from annotated type
+// declarations, in the spirit of mockgen/moq for interfaces. Point it at a
+// package directory, annotate the types you want wired with a
+// "// +grab:generate" doc comment (see generate.go for the directive
+// grammar), and it emits a "<file>_grab.go" file per annotated source file
+// containing the generated grabbers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const generatedSuffix = "_grab.go"
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to scan for +grab:generate annotations")
+	mock := flag.Bool("mock", false, "also emit a Mock<Name> helper for each generated grabber")
+	flag.Parse()
+
+	if err := run(*dir, *mock); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dir string, withMock bool) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file, generatedSuffix) {
+			continue
+		}
+
+		pf, err := parseFile(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		if len(pf.specs) == 0 {
+			continue
+		}
+
+		out, err := generate(pf, withMock)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		outPath := strings.TrimSuffix(file, ".go") + generatedSuffix
+		if err := os.WriteFile(outPath, out, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package testdata
+
+import "example.com/other/db"
+
+// +grab:generate NewRemote
+type Remote struct {
+	Conn *db.Conn
+}
+
+func NewRemote(c *db.Conn) (*Remote, error) {
+	return &Remote{Conn: c}, nil
+}
@@ -0,0 +1,15 @@
+package testdata
+
+// +grab:generate
+type Plain struct {
+	Name string
+}
+
+// +grab:generate NewWired
+type Wired struct {
+	Plain *Plain
+}
+
+func NewWired(p *Plain) (*Wired, error) {
+	return &Wired{Plain: p}, nil
+}
@@ -2,6 +2,7 @@ package grab
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -19,6 +20,8 @@ const (
 	ErrDestInterfaceMustBePointer = E("dest interface must be pass as pointer")
 	ErrCircularDependency         = E("circular dependency detected")
 	ErrAlreadyMocked              = E("already mocked")
+	ErrTypeMismatch               = E("resolved value does not match the requested type")
+	ErrContainerNotResolvable     = E("container does not support the generics Get API")
 )
 
 var empty struct{}
@@ -49,54 +52,302 @@ type Container interface {
 	Get(dest interface{}, g Grabber) error
 }
 
+// resolver lets the generics API in typed.go read a Grabber's value
+// directly, without the reflect-based assign.
+type resolver interface {
+	resolve(g Grabber) (interface{}, error)
+}
+
+// edgeResolver is resolver plus the parent/top threading Close needs to
+// build the dependency DAG.
+type edgeResolver interface {
+	resolveFor(top edgeResolver, parent Grabber, g Grabber) (interface{}, error)
+}
+
+// scoped is the Container handed to a Grabber's Grab method, routing nested
+// Get calls back through top.
+type scoped struct {
+	top    edgeResolver
+	parent Grabber
+}
+
+func (s *scoped) Get(dest interface{}, g Grabber) error {
+	value, err := s.top.resolveFor(s.top, s.parent, g)
+	if err != nil {
+		return err
+	}
+
+	return assign(dest, value)
+}
+
+// resolve makes scoped satisfy resolver too, for nested generics Get calls.
+func (s *scoped) resolve(g Grabber) (interface{}, error) {
+	return s.top.resolveFor(s.top, s.parent, g)
+}
+
 // Repository is an implementation for Container interface. It is thread safe
 // it also support circular dependency detection.
 type Repository struct {
 	grabbers map[Grabber]interface{}
 	pendding map[Grabber]struct{}
+	edges    map[Grabber]map[Grabber]struct{} // parent -> children observed via Get
+	hooks    map[Grabber][]func(interface{}) error
+	subs     map[Grabber][]*subscription
+	parent   *Repository
 	mtx      sync.RWMutex
 }
 
-// Get accepts a pointer to any types (struct or interface), and grabber.
-func (r *Repository) Get(dest interface{}, g Grabber) error {
-	var err error
+// subscription backs a single Subscribe call.
+type subscription struct {
+	ch chan interface{}
+}
+
+// resolve looks up or grabs the value for g without assigning it anywhere.
+func (r *Repository) resolve(g Grabber) (interface{}, error) {
+	return r.resolveFor(r, nil, g)
+}
+
+// resolveFor is the shared implementation behind resolve and Get; parent
+// records a parent -> g edge for Close's dependency DAG.
+func (r *Repository) resolveFor(top edgeResolver, parent Grabber, g Grabber) (interface{}, error) {
+	if parent != nil {
+		r.recordEdge(parent, g)
+	}
+
+	if value, ok := r.peek(g); ok {
+		return value, nil
+	}
+
+	if r.pendingAnywhere(g) {
+		return nil, ErrCircularDependency
+	}
+
+	r.mtx.Lock()
+	r.pendding[g] = empty
+	r.mtx.Unlock()
+
+	value, err := g.Grab(&scoped{top: top, parent: g})
+	if err != nil {
+		r.mtx.Lock()
+		delete(r.pendding, g)
+		r.mtx.Unlock()
+		return nil, err
+	}
+
+	r.mtx.Lock()
+	r.grabbers[g] = value
+	delete(r.pendding, g)
+	r.mtx.Unlock()
 
-	// we need the read lock here to make sure that
-	// no one can update the grabbers map
+	r.notify(g, value)
+
+	return value, nil
+}
+
+// peek looks up g's cached value, reading through to the parent scope.
+func (r *Repository) peek(g Grabber) (interface{}, bool) {
 	r.mtx.RLock()
 	value, ok := r.grabbers[g]
 	r.mtx.RUnlock()
 
 	if ok {
-		return assign(dest, value)
+		return value, true
+	}
+
+	if r.parent != nil {
+		return r.parent.peek(g)
 	}
 
+	return nil, false
+}
+
+// pendingAnywhere reports whether g is being resolved in this Repository
+// or one of its ancestor scopes.
+func (r *Repository) pendingAnywhere(g Grabber) bool {
 	r.mtx.RLock()
-	_, ok = r.pendding[g]
+	_, ok := r.pendding[g]
 	r.mtx.RUnlock()
 
 	if ok {
-		return ErrCircularDependency
+		return true
 	}
 
+	if r.parent != nil {
+		return r.parent.pendingAnywhere(g)
+	}
+
+	return false
+}
+
+// recordEdge notes that parent's Grab func asked for child.
+func (r *Repository) recordEdge(parent, child Grabber) {
 	r.mtx.Lock()
-	r.pendding[g] = empty
+	defer r.mtx.Unlock()
+
+	if r.edges[parent] == nil {
+		r.edges[parent] = make(map[Grabber]struct{})
+	}
+	r.edges[parent][child] = empty
+}
+
+// Scope returns a child Repository that reads through to r's cached values
+// but only stores what it resolves itself, so Close on the child only
+// tears down its own grabbers.
+func (r *Repository) Scope() *Repository {
+	return &Repository{
+		grabbers: make(map[Grabber]interface{}),
+		pendding: make(map[Grabber]struct{}),
+		edges:    make(map[Grabber]map[Grabber]struct{}),
+		hooks:    make(map[Grabber][]func(interface{}) error),
+		subs:     make(map[Grabber][]*subscription),
+		parent:   r,
+	}
+}
+
+// Override seeds g's cached value in this scope directly, bypassing Grab.
+// It is scope-local: it never touches a parent Repository's cache.
+func (r *Repository) Override(g Grabber, val interface{}) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.grabbers[g] = val
+}
+
+// Subscribe returns a channel that receives g's value whenever it is
+// created or replaced, and an unsubscribe func to release it.
+func (r *Repository) Subscribe(g Grabber) (<-chan interface{}, func()) {
+	sub := &subscription{ch: make(chan interface{}, 1)}
+
+	r.mtx.Lock()
+	r.subs[g] = append(r.subs[g], sub)
 	r.mtx.Unlock()
 
-	value, err = g.Grab(r)
-	if err != nil {
+	unsubscribe := func() {
 		r.mtx.Lock()
-		delete(r.pendding, g)
-		r.mtx.Unlock()
+		defer r.mtx.Unlock()
+
+		subs := r.subs[g]
+		for i, s := range subs {
+			if s == sub {
+				r.subs[g] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return sub.ch, unsubscribe
+}
+
+// notify delivers val to every subscriber of g, dropping the oldest
+// undelivered value instead of blocking.
+func (r *Repository) notify(g Grabber, val interface{}) {
+	r.mtx.RLock()
+	subs := append([]*subscription(nil), r.subs[g]...)
+	r.mtx.RUnlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- val:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- val:
+			default:
+			}
+		}
+	}
+}
+
+// Replace sets g's cached value in this scope and notifies its subscribers,
+// as if g had just been resolved to val.
+func (r *Repository) Replace(g Grabber, val interface{}) error {
+	r.Override(g, val)
+	r.notify(g, val)
+	return nil
+}
+
+// Get accepts a pointer to any types (struct or interface), and grabber.
+func (r *Repository) Get(dest interface{}, g Grabber) error {
+	value, err := r.resolveFor(r, nil, g)
+	if err != nil {
 		return err
 	}
 
+	return assign(dest, value)
+}
+
+// OnClose registers fn to run with g's value when Close is called.
+func (r *Repository) OnClose(g Grabber, fn func(interface{}) error) {
 	r.mtx.Lock()
-	r.grabbers[g] = value
-	delete(r.pendding, g)
+	defer r.mtx.Unlock()
+
+	r.hooks[g] = append(r.hooks[g], fn)
+}
+
+// Close tears down every grabber this Repository has resolved, in reverse
+// dependency order, and returns any hook errors together.
+func (r *Repository) Close() error {
+	r.mtx.Lock()
+	order := make([]Grabber, 0, len(r.grabbers))
+	visited := make(map[Grabber]bool, len(r.grabbers))
+
+	var visit func(g Grabber)
+	visit = func(g Grabber) {
+		if visited[g] {
+			return
+		}
+		visited[g] = true
+
+		for child := range r.edges[g] {
+			visit(child)
+		}
+
+		order = append(order, g)
+	}
+
+	for g := range r.grabbers {
+		visit(g)
+	}
 	r.mtx.Unlock()
 
-	return assign(dest, value)
+	var errs []error
+
+	// order currently lists each grabber after the children it depends on
+	// (post-order); walking it back to front closes dependents first.
+	for i := len(order) - 1; i >= 0; i-- {
+		g := order[i]
+
+		r.mtx.RLock()
+		hooks := r.hooks[g]
+		value := r.grabbers[g]
+		r.mtx.RUnlock()
+
+		for _, hook := range hooks {
+			if err := hook(value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return multiError(errs)
+	}
+
+	return nil
+}
+
+// multiError aggregates the errors returned by Close's teardown hooks.
+type multiError []error
+
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
 }
 
 // New initialize the Repository container. Repository is Thread-Safe
@@ -104,6 +355,9 @@ func New() *Repository {
 	return &Repository{
 		grabbers: make(map[Grabber]interface{}, 0),
 		pendding: make(map[Grabber]struct{}, 0),
+		edges:    make(map[Grabber]map[Grabber]struct{}),
+		hooks:    make(map[Grabber][]func(interface{}) error),
+		subs:     make(map[Grabber][]*subscription),
 	}
 }
 
@@ -111,20 +365,97 @@ func New() *Repository {
 // It also wrap Get to returns value that has already been mocked
 type RepositoryWithMock struct {
 	*Repository
-	mocked map[Grabber]interface{}
+	mocked       map[Grabber]interface{}
+	parentMocked *RepositoryWithMock
 }
 
-// Get this method has been overrid to provide mock system
-func (r *RepositoryWithMock) Get(dest interface{}, g Grabber) error {
-	r.mtx.Lock()
-	if value, ok := r.mocked[g]; ok {
+// resolve overrides Repository.resolve to return the mocked value when present.
+func (r *RepositoryWithMock) resolve(g Grabber) (interface{}, error) {
+	return r.resolveFor(r, nil, g)
+}
+
+// resolveFor overrides Repository.resolveFor so mocks apply to nested Get calls too.
+func (r *RepositoryWithMock) resolveFor(top edgeResolver, parent Grabber, g Grabber) (interface{}, error) {
+	if value, ok := r.peekMocked(g); ok {
+		if parent != nil {
+			r.recordEdge(parent, g)
+		}
+		return value, nil
+	}
+
+	// go back to regular routine
+	return r.Repository.resolveFor(top, parent, g)
+}
+
+// peekMocked looks up g in this scope's mocks, reading through to an ancestor's.
+func (r *RepositoryWithMock) peekMocked(g Grabber) (interface{}, bool) {
+	r.mtx.RLock()
+	value, ok := r.mocked[g]
+	r.mtx.RUnlock()
+
+	if ok {
+		return value, true
+	}
+
+	if r.parentMocked != nil {
+		return r.parentMocked.peekMocked(g)
+	}
+
+	return nil, false
+}
+
+// Scope returns a child RepositoryWithMock mirroring Repository.Scope,
+// reading through to r's mocks as well as its cached values.
+func (r *RepositoryWithMock) Scope() *RepositoryWithMock {
+	return &RepositoryWithMock{
+		Repository:   r.Repository.Scope(),
+		mocked:       make(map[Grabber]interface{}),
+		parentMocked: r,
+	}
+}
+
+// Replace updates g's mock in place if it is mocked anywhere in the scope
+// chain, falling back to Repository.Replace otherwise.
+func (r *RepositoryWithMock) Replace(g Grabber, val interface{}) error {
+	if _, mockedAnywhere := r.peekMocked(g); mockedAnywhere {
+		r.mtx.Lock()
+		r.mocked[g] = val
 		r.mtx.Unlock()
-		return assign(dest, value)
+		r.notify(g, val)
+		return nil
 	}
+
+	return r.Repository.Replace(g, val)
+}
+
+// Unmock removes g's mock. If g has subscribers, it is resolved right away
+// so they're notified with the real value.
+func (r *RepositoryWithMock) Unmock(g Grabber) {
+	r.mtx.Lock()
+	delete(r.mocked, g)
 	r.mtx.Unlock()
 
-	// go back to regular routine
-	return r.Repository.Get(dest, g)
+	r.mtx.RLock()
+	hasSubscribers := len(r.subs[g]) > 0
+	r.mtx.RUnlock()
+
+	if !hasSubscribers {
+		return
+	}
+
+	if value, err := r.resolveFor(r, nil, g); err == nil {
+		r.notify(g, value)
+	}
+}
+
+// Get this method has been overrid to provide mock system
+func (r *RepositoryWithMock) Get(dest interface{}, g Grabber) error {
+	value, err := r.resolveFor(r, nil, g)
+	if err != nil {
+		return err
+	}
+
+	return assign(dest, value)
 }
 
 // Mock simply return a new value to provided Grabber
@@ -163,7 +163,7 @@ type MockTestData struct {
 }
 
 func TestMockDependency(t *testing.T) {
-	container := grab.New()
+	container := grab.Mock()
 
 	GrabTest := grab.Func(func(c grab.Container) (interface{}, error) {
 		return &MockTestData{
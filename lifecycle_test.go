@@ -0,0 +1,69 @@
+package grab_test
+
+import (
+	"testing"
+
+	"github.com/nulloop/grab/v2"
+)
+
+type db struct{}
+type server struct{ db *db }
+
+func TestCloseOrdersDependentsBeforeDependencies(t *testing.T) {
+	c := grab.New()
+
+	var closed []string
+
+	grabDB := grab.Func(func(c grab.Container) (interface{}, error) {
+		return &db{}, nil
+	})
+	c.OnClose(grabDB, func(interface{}) error {
+		closed = append(closed, "db")
+		return nil
+	})
+
+	grabServer := grab.Func(func(c grab.Container) (interface{}, error) {
+		var d *db
+		if err := c.Get(&d, grabDB); err != nil {
+			return nil, err
+		}
+		return &server{db: d}, nil
+	})
+	c.OnClose(grabServer, func(interface{}) error {
+		closed = append(closed, "server")
+		return nil
+	})
+
+	var s *server
+	if err := c.Get(&s, grabServer); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(closed) != 2 || closed[0] != "server" || closed[1] != "db" {
+		t.Fatalf("expected [server db], got %v", closed)
+	}
+}
+
+func TestCircularDependencyStillDetected(t *testing.T) {
+	c := grab.New()
+
+	var grabA, grabB grab.Grabber
+	grabA = grab.Func(func(c grab.Container) (interface{}, error) {
+		var v interface{}
+		return nil, c.Get(&v, grabB)
+	})
+	grabB = grab.Func(func(c grab.Container) (interface{}, error) {
+		var v interface{}
+		return nil, c.Get(&v, grabA)
+	})
+
+	var v interface{}
+	err := c.Get(&v, grabA)
+	if err != grab.ErrCircularDependency {
+		t.Fatalf("expected circular dependency error, got %v", err)
+	}
+}
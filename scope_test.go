@@ -0,0 +1,120 @@
+package grab_test
+
+import (
+	"testing"
+
+	"github.com/nulloop/grab/v2"
+)
+
+type cfg struct{ env string }
+
+func TestScopeReadsThroughParentAndIsolatesNewValues(t *testing.T) {
+	root := grab.New()
+
+	grabCfg := grab.Func(func(c grab.Container) (interface{}, error) {
+		return &cfg{env: "root"}, nil
+	})
+
+	var rootCfg *cfg
+	if err := root.Get(&rootCfg, grabCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	child := root.Scope()
+
+	var childCfg *cfg
+	if err := child.Get(&childCfg, grabCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if childCfg != rootCfg {
+		t.Fatal("scope should read through to the parent's cached value")
+	}
+
+	grabScoped := grab.Func(func(c grab.Container) (interface{}, error) {
+		return &dummy{name: "scoped-only"}, nil
+	})
+
+	var d *dummy
+	if err := child.Get(&d, grabScoped); err != nil {
+		t.Fatal(err)
+	}
+
+	var rootD *dummy
+	err := root.Get(&rootD, grabScoped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rootD == d {
+		t.Fatal("root should not see the child scope's resolved value")
+	}
+}
+
+func TestOverrideIsScopeLocal(t *testing.T) {
+	root := grab.New()
+	child := root.Scope()
+
+	grabCfg := grab.Func(func(c grab.Container) (interface{}, error) {
+		return &cfg{env: "root"}, nil
+	})
+
+	override := &cfg{env: "test"}
+	child.Override(grabCfg, override)
+
+	var got *cfg
+	if err := child.Get(&got, grabCfg); err != nil {
+		t.Fatal(err)
+	}
+	if got != override {
+		t.Fatal("child should see the overridden value")
+	}
+
+	var rootGot *cfg
+	if err := root.Get(&rootGot, grabCfg); err != nil {
+		t.Fatal(err)
+	}
+	if rootGot == override {
+		t.Fatal("override must not leak into the parent Repository")
+	}
+}
+
+func TestScopeCloseOnlyTearsDownOwnGrabbers(t *testing.T) {
+	root := grab.New()
+	grabRoot := grab.Func(func(c grab.Container) (interface{}, error) {
+		return &dummy{name: "root"}, nil
+	})
+
+	var rootClosed, childClosed bool
+	var rd *dummy
+	if err := root.Get(&rd, grabRoot); err != nil {
+		t.Fatal(err)
+	}
+	root.OnClose(grabRoot, func(interface{}) error {
+		rootClosed = true
+		return nil
+	})
+
+	child := root.Scope()
+	grabChild := grab.Func(func(c grab.Container) (interface{}, error) {
+		return &dummy{name: "child"}, nil
+	})
+	var cd *dummy
+	if err := child.Get(&cd, grabChild); err != nil {
+		t.Fatal(err)
+	}
+	child.OnClose(grabChild, func(interface{}) error {
+		childClosed = true
+		return nil
+	})
+
+	if err := child.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !childClosed {
+		t.Fatal("child.Close() should run the child's own hooks")
+	}
+	if rootClosed {
+		t.Fatal("child.Close() must not tear down the parent's grabbers")
+	}
+}
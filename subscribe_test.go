@@ -0,0 +1,136 @@
+package grab_test
+
+import (
+	"testing"
+
+	"github.com/nulloop/grab/v2"
+)
+
+func TestSubscribeReceivesInitialResolution(t *testing.T) {
+	c := grab.New()
+
+	ch, unsubscribe := c.Subscribe(grabFoo)
+	defer unsubscribe()
+
+	var v *foo
+	if err := c.Get(&v, grabFoo); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-ch:
+		if got.(*foo) != v {
+			t.Fatal("subscriber should receive the initial resolved value")
+		}
+	default:
+		t.Fatal("expected a value on the subscription channel after the first Get")
+	}
+}
+
+func TestSubscribeReceivesReplacedValue(t *testing.T) {
+	c := grab.New()
+
+	ch, unsubscribe := c.Subscribe(grabFoo)
+	defer unsubscribe()
+
+	replacement := &foo{name: "swapped"}
+	if err := c.Replace(grabFoo, replacement); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case v := <-ch:
+		if v.(*foo) != replacement {
+			t.Fatal("subscriber did not receive the replaced value")
+		}
+	default:
+		t.Fatal("expected a value on the subscription channel")
+	}
+
+	var got *foo
+	if err := c.Get(&got, grabFoo); err != nil {
+		t.Fatal(err)
+	}
+	if got != replacement {
+		t.Fatal("Get should return the replaced value after Replace")
+	}
+}
+
+func TestSubscribeDropsOldestOnFullChannel(t *testing.T) {
+	c := grab.New()
+	ch, unsubscribe := c.Subscribe(grabFoo)
+	defer unsubscribe()
+
+	first := &foo{name: "first"}
+	second := &foo{name: "second"}
+
+	c.Replace(grabFoo, first)
+	c.Replace(grabFoo, second)
+
+	v := <-ch
+	if v.(*foo) != second {
+		t.Fatal("expected the newest value to win over a full buffered channel")
+	}
+}
+
+func TestMockReplaceAndUnmockRoundtrip(t *testing.T) {
+	c := grab.Mock()
+
+	mockVal := &foo{name: "mocked"}
+	if err := c.Mock(grabFoo, mockVal); err != nil {
+		t.Fatal(err)
+	}
+
+	replaced := &foo{name: "replaced-mock"}
+	if err := c.Replace(grabFoo, replaced); err != nil {
+		t.Fatal(err)
+	}
+
+	var got *foo
+	if err := c.Get(&got, grabFoo); err != nil {
+		t.Fatal(err)
+	}
+	if got != replaced {
+		t.Fatal("Replace should update the mocked value in place")
+	}
+
+	c.Unmock(grabFoo)
+
+	var real *foo
+	if err := c.Get(&real, grabFoo); err != nil {
+		t.Fatal(err)
+	}
+	if real == replaced {
+		t.Fatal("Get after Unmock should resolve the underlying grabber, not the old mock")
+	}
+}
+
+func TestScopedMockReplaceOverridesAncestorMock(t *testing.T) {
+	parent := grab.Mock()
+	if err := parent.Mock(grabFoo, &foo{name: "parent-mock"}); err != nil {
+		t.Fatal(err)
+	}
+
+	child := parent.Scope()
+
+	replacement := &foo{name: "child-replacement"}
+	if err := child.Replace(grabFoo, replacement); err != nil {
+		t.Fatal(err)
+	}
+
+	var got *foo
+	if err := child.Get(&got, grabFoo); err != nil {
+		t.Fatal(err)
+	}
+	if got != replacement {
+		t.Fatalf("expected child.Get to see the local replacement, got %v", got.name)
+	}
+
+	var parentGot *foo
+	if err := parent.Get(&parentGot, grabFoo); err != nil {
+		t.Fatal(err)
+	}
+	if parentGot == replacement {
+		t.Fatal("replacing in a child scope must not leak into the parent's mock")
+	}
+}
@@ -0,0 +1,55 @@
+package grab
+
+// TypedGrabber is a type-safe counterpart of Grabber. Where a plain Grabber
+// forces every caller through the reflect-based assign, a TypedGrabber lets
+// Get/MustGet hand back a concrete T directly.
+type TypedGrabber[T any] struct {
+	fn func(c Container) (T, error)
+}
+
+// Grab implements Grabber so a TypedGrabber can still be passed anywhere a
+// Grabber is expected, e.g. to Container.Get or Repository.OnClose.
+func (t *TypedGrabber[T]) Grab(c Container) (interface{}, error) {
+	return t.fn(c)
+}
+
+// TypedFunc is the generics counterpart of Func.
+func TypedFunc[T any](fn func(c Container) (T, error)) *TypedGrabber[T] {
+	return &TypedGrabber[T]{fn: fn}
+}
+
+// Get resolves g against c and returns a concrete T. Containers that
+// implement resolver (Repository and RepositoryWithMock) hand back the
+// cached value directly, so the only type check left is a plain type
+// assertion - no reflect involved on this path.
+func Get[T any](c Container, g *TypedGrabber[T]) (T, error) {
+	var zero T
+
+	res, ok := c.(resolver)
+	if !ok {
+		return zero, ErrContainerNotResolvable
+	}
+
+	value, err := res.resolve(g)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, ErrTypeMismatch
+	}
+
+	return typed, nil
+}
+
+// MustGet is like Get but panics if g cannot be resolved or resolves to the
+// wrong type.
+func MustGet[T any](c Container, g *TypedGrabber[T]) T {
+	value, err := Get(c, g)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
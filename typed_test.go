@@ -0,0 +1,72 @@
+package grab_test
+
+import (
+	"testing"
+
+	"github.com/nulloop/grab/v2"
+)
+
+var grabCount = grab.TypedFunc(func(c grab.Container) (int, error) {
+	return 42, nil
+})
+
+func TestTypedGetAndMustGetRoundTrip(t *testing.T) {
+	c := grab.New()
+
+	v, err := grab.Get(c, grabCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 {
+		t.Fatalf("expected 42, got %d", v)
+	}
+
+	if got := grab.MustGet(c, grabCount); got != 42 {
+		t.Fatalf("expected 42, got %d", got)
+	}
+}
+
+func TestTypedGetMismatchedMockValue(t *testing.T) {
+	c := grab.Mock()
+
+	if err := c.Mock(grabCount, "not an int"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := grab.Get(c, grabCount)
+	if err != grab.ErrTypeMismatch {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestMustGetPanicsOnError(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected MustGet to panic")
+		}
+	}()
+
+	c := grab.New()
+	grabFails := grab.TypedFunc(func(c grab.Container) (int, error) {
+		return 0, grab.ErrTypeMismatch
+	})
+
+	grab.MustGet(c, grabFails)
+}
+
+func TestTypedGetFromNestedGrab(t *testing.T) {
+	c := grab.New()
+
+	grabDoubled := grab.TypedFunc(func(c grab.Container) (int, error) {
+		n := grab.MustGet(c, grabCount)
+		return n * 2, nil
+	})
+
+	doubled, err := grab.Get(c, grabDoubled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doubled != 84 {
+		t.Fatalf("expected 84, got %d", doubled)
+	}
+}